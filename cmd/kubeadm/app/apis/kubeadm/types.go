@@ -0,0 +1,133 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+// InitConfiguration contains a list of elements that is specific "kubeadm init"-only runtime
+// information.
+type InitConfiguration struct {
+	ClusterConfiguration
+
+	// APIEndpoint lets you customize the address and port on which the API server advertises to members of the cluster.
+	APIEndpoint APIEndpoint
+
+	// NodeRegistration holds fields that relate to registering the new control-plane node to the cluster.
+	NodeRegistration NodeRegistrationOptions
+}
+
+// APIEndpoint struct contains elements of API server instance deployed on a node.
+type APIEndpoint struct {
+	// AdvertiseAddress sets the IP address for the API server to advertise.
+	AdvertiseAddress string
+	// BindPort sets the secure port for the API Server to bind to. Defaults to 6443.
+	BindPort int32
+}
+
+// NodeRegistrationOptions holds fields that relate to registering a new control-plane or
+// worker node to the cluster.
+type NodeRegistrationOptions struct {
+	// Name is the `.Metadata.Name` field of the Node API object that will be created in this `kubeadm init` or `kubeadm join` operation.
+	Name string
+}
+
+// ClusterConfiguration contains cluster-wide configuration for a kubeadm cluster.
+type ClusterConfiguration struct {
+	// Etcd holds configuration for etcd.
+	Etcd Etcd
+
+	// KubernetesVersion is the target version of the control plane.
+	KubernetesVersion string
+}
+
+// Etcd contains elements describing Etcd configuration.
+type Etcd struct {
+	// Local provides configuration knobs for configuring the local etcd instance.
+	// Local and External are mutually exclusive.
+	Local *LocalEtcd
+
+	// External describes how to connect to an external etcd cluster.
+	// Local and External are mutually exclusive.
+	External *ExternalEtcd
+}
+
+// MemberJoinMode describes how a new local etcd member joins an already-running cluster.
+type MemberJoinMode string
+
+const (
+	// MemberJoinVoter adds the new member directly as a full voting member. This is the
+	// historical kubeadm behavior, and the default when MemberJoinMode is unset.
+	MemberJoinVoter MemberJoinMode = "Voter"
+	// MemberJoinLearner adds the new member as a non-voting learner first, and only
+	// promotes it to a voting member once it has caught up with the cluster leader. This
+	// avoids the quorum-loss window a straight voter-add can create on an existing cluster.
+	MemberJoinLearner MemberJoinMode = "Learner"
+)
+
+// LocalEtcd describes that kubeadm should run an etcd cluster locally.
+type LocalEtcd struct {
+	// Image specifies which container image to use for running etcd. If empty, automatically populated by kubeadm using the image repository and default etcd version.
+	Image string
+
+	// DataDir is the directory etcd will place its data.
+	DataDir string
+
+	// ExtraArgs are extra arguments provided to the etcd binary when run inside a static pod.
+	ExtraArgs map[string]string
+
+	// ServerCertSANs sets extra Subject Alternative Names for the etcd server signing cert.
+	ServerCertSANs []string
+	// PeerCertSANs sets extra Subject Alternative Names for the etcd peer signing cert.
+	PeerCertSANs []string
+
+	// AdditionalPeerURLs lists extra peer URLs, beyond the one derived from AdvertiseAddress,
+	// that this member listens on, advertises, and includes in its initial-cluster entry.
+	// This lets a member keep an old peer URL reachable alongside a new one while a live
+	// control-plane migration is in progress.
+	AdditionalPeerURLs []string
+	// AdditionalClientURLs lists extra client URLs, beyond the ones derived from
+	// AdvertiseAddress, that this member listens on and advertises.
+	AdditionalClientURLs []string
+
+	// MemberJoinMode controls how this member joins an existing etcd cluster: as a full
+	// voter right away (MemberJoinVoter, the default) or as a non-voting learner that is
+	// promoted once it has caught up (MemberJoinLearner). It has no effect when bootstrapping
+	// the first member of a cluster.
+	MemberJoinMode MemberJoinMode
+}
+
+// ExternalEtcd describes an external etcd cluster.
+type ExternalEtcd struct {
+	// Endpoints of etcd members. Useful for using external etcd.
+	Endpoints []string
+
+	// CAFile is an SSL Certificate Authority file used to secure etcd communication.
+	CAFile string
+
+	// CertFile is an SSL certification file used to secure etcd communication.
+	CertFile string
+
+	// KeyFile is an SSL key file used to secure etcd communication.
+	KeyFile string
+
+	// EnableProxy makes kubeadm run a local `etcd grpc-proxy` static pod in front of
+	// Endpoints, so that components that only know how to talk to a local etcd (for example
+	// kube-apiserver's loopback storage config) keep working unchanged even though the
+	// cluster is actually using an external etcd.
+	EnableProxy bool
+
+	// ProxyExtraArgs are extra arguments provided to the `etcd grpc-proxy start` command.
+	ProxyExtraArgs map[string]string
+}
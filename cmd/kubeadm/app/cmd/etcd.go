@@ -0,0 +1,243 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	etcdphase "k8s.io/kubernetes/cmd/kubeadm/app/phases/etcd"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/etcd/backup"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+// NewCmdEtcd returns the `kubeadm etcd` command and its snapshot and join subcommands.
+func NewCmdEtcd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "etcd",
+		Short: "Commands for operating the kubeadm-managed local etcd cluster",
+	}
+	cmd.AddCommand(newCmdEtcdSnapshot())
+	cmd.AddCommand(newCmdEtcdJoin())
+	return cmd
+}
+
+// newCmdEtcdJoin renders and (re)writes the local etcd static pod manifest for a node that is
+// joining an existing cluster, giving a user-facing entry point to the MemberJoinMode and
+// learner-catch-up flags phases/etcd/join_flags.go adds.
+func newCmdEtcdJoin() *cobra.Command {
+	var (
+		name              string
+		advertiseAddress  string
+		dataDir           string
+		image             string
+		memberJoinMode    string
+		initialClusterRaw string
+		manifestDir       string
+	)
+	joinCfg := etcdphase.DefaultLearnerJoinConfiguration()
+
+	cmd := &cobra.Command{
+		Use:   "join",
+		Short: "Write the local etcd static pod manifest for a node joining an existing cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mode := kubeadmapi.MemberJoinMode(memberJoinMode)
+			if mode != kubeadmapi.MemberJoinVoter && mode != kubeadmapi.MemberJoinLearner {
+				return fmt.Errorf("invalid --member-join-mode %q: must be %q or %q", memberJoinMode, kubeadmapi.MemberJoinVoter, kubeadmapi.MemberJoinLearner)
+			}
+
+			initialCluster, err := parseInitialCluster(initialClusterRaw)
+			if err != nil {
+				return err
+			}
+
+			cfg := &kubeadmapi.InitConfiguration{
+				APIEndpoint:      kubeadmapi.APIEndpoint{AdvertiseAddress: advertiseAddress},
+				NodeRegistration: kubeadmapi.NodeRegistrationOptions{Name: name},
+				ClusterConfiguration: kubeadmapi.ClusterConfiguration{
+					Etcd: kubeadmapi.Etcd{
+						Local: &kubeadmapi.LocalEtcd{
+							DataDir:        dataDir,
+							Image:          image,
+							MemberJoinMode: mode,
+						},
+					},
+				},
+			}
+
+			return etcdphase.CreateLocalEtcdStaticPodManifestFileWithJoin(manifestDir, cfg, initialCluster, joinCfg)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&name, "name", "", "The name this member registers in the etcd cluster as.")
+	flags.StringVar(&advertiseAddress, "advertise-address", "", "The IP address this member advertises to the rest of the cluster.")
+	flags.StringVar(&dataDir, "data-dir", "/var/lib/etcd", "Directory etcd will place its data in.")
+	flags.StringVar(&image, "image", "", "Container image to run etcd from.")
+	flags.StringVar(&memberJoinMode, "member-join-mode", string(kubeadmapi.MemberJoinVoter),
+		fmt.Sprintf("How this member joins the cluster named in --initial-cluster: %q adds it directly as a voter, %q adds it as a non-voting learner that is promoted once caught up.", kubeadmapi.MemberJoinVoter, kubeadmapi.MemberJoinLearner))
+	flags.StringVar(&initialClusterRaw, "initial-cluster", "", "Comma-separated name=peerURL pairs describing the cluster being joined, including this member.")
+	flags.StringVar(&manifestDir, "manifest-dir", kubeadmconstants.KubernetesDir+"/"+kubeadmconstants.ManifestsSubDirName, "Directory kubeadm writes static pod manifests to.")
+	etcdphase.AddLearnerJoinFlags(flags, &joinCfg)
+	return cmd
+}
+
+// parseInitialCluster parses a "name1=url1,name2=url2" string, the same format etcd's own
+// --initial-cluster flag uses, into the Member list the etcd phase expects.
+func parseInitialCluster(raw string) ([]etcdutil.Member, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var members []etcdutil.Member
+	for _, pair := range strings.Split(raw, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid --initial-cluster entry %q: expected name=peerURL", pair)
+		}
+		members = append(members, etcdutil.Member{Name: parts[0], PeerURL: parts[1]})
+	}
+	return members, nil
+}
+
+func newCmdEtcdSnapshot() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save, restore or schedule snapshots of the local etcd cluster",
+	}
+	cmd.AddCommand(newCmdEtcdSnapshotSave())
+	cmd.AddCommand(newCmdEtcdSnapshotRestore())
+	cmd.AddCommand(newCmdEtcdSnapshotSchedule())
+	return cmd
+}
+
+func newCmdEtcdSnapshotSave() *cobra.Command {
+	var endpoint, dbPath, manifestPath string
+	var backendName, backendDir, backendBucket, backendPrefix string
+
+	cmd := &cobra.Command{
+		Use:   "save",
+		Short: "Take a point-in-time snapshot of the local etcd cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := &kubeadmapi.InitConfiguration{}
+			if manifestPath == "" {
+				manifestPath = dbPath + ".json"
+			}
+			backend, err := newBackend(backendName, backendDir, backendBucket, backendPrefix)
+			if err != nil {
+				return err
+			}
+			return backup.Save(cfg, endpoint, dbPath, manifestPath, backend)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&endpoint, "endpoint", fmt.Sprintf("https://127.0.0.1:%d", kubeadmconstants.EtcdListenClientPort), "The etcd client endpoint to snapshot.")
+	flags.StringVar(&dbPath, "output", "/var/backups/etcd/snapshot.db", "Where to write the snapshot `.db` file.")
+	flags.StringVar(&manifestPath, "manifest-output", "", "Where to write the snapshot manifest. Defaults to <output>.json.")
+	flags.StringVar(&backendName, "backend", "", "Where to additionally upload the snapshot: \"local\", \"s3\" or \"gcs\". Defaults to not uploading anywhere. The \"s3\" and \"gcs\" backends are not yet implemented and will fail at upload time.")
+	flags.StringVar(&backendDir, "backend-dir", "/var/backups/etcd", "Destination directory for the \"local\" backend.")
+	flags.StringVar(&backendBucket, "backend-bucket", "", "Destination bucket for the \"s3\"/\"gcs\" backends.")
+	flags.StringVar(&backendPrefix, "backend-prefix", "", "Object key prefix for the \"s3\"/\"gcs\" backends.")
+	return cmd
+}
+
+// newBackend builds the backup.Backend named by name, or returns a nil Backend (meaning: don't
+// upload anywhere) when name is empty.
+func newBackend(name, dir, bucket, prefix string) (backup.Backend, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "local":
+		return &backup.LocalBackend{Dir: dir}, nil
+	case "s3":
+		return &backup.S3Backend{Bucket: bucket, Prefix: prefix}, nil
+	case "gcs":
+		return &backup.GCSBackend{Bucket: bucket, Prefix: prefix}, nil
+	default:
+		return nil, fmt.Errorf("invalid --backend %q: must be \"local\", \"s3\" or \"gcs\"", name)
+	}
+}
+
+func newCmdEtcdSnapshotRestore() *cobra.Command {
+	var restoreCfg backup.RestoreConfiguration
+	var advertiseAddress, image, dbPath, manifestDir string
+
+	cmd := &cobra.Command{
+		Use:   "restore [snapshot]",
+		Short: "Restore the local etcd cluster from a snapshot taken with `snapshot save`",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbPath = args[0]
+			cfg := &kubeadmapi.InitConfiguration{
+				APIEndpoint:      kubeadmapi.APIEndpoint{AdvertiseAddress: advertiseAddress},
+				NodeRegistration: kubeadmapi.NodeRegistrationOptions{Name: restoreCfg.Name},
+				ClusterConfiguration: kubeadmapi.ClusterConfiguration{
+					Etcd: kubeadmapi.Etcd{
+						Local: &kubeadmapi.LocalEtcd{
+							DataDir: restoreCfg.DataDir,
+							Image:   image,
+						},
+					},
+				},
+			}
+			return backup.Restore(cfg, restoreCfg, dbPath, manifestDir)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&restoreCfg.Name, "name", "", "The member name the restored etcd instance runs as.")
+	flags.StringVar(&restoreCfg.DataDir, "data-dir", "/var/lib/etcd", "Directory to write the restored data into.")
+	flags.StringSliceVar(&restoreCfg.InitialAdvertisePeerURLs, "initial-advertise-peer-urls", nil, "Peer URL the restored member advertises.")
+	flags.StringVar(&restoreCfg.InitialCluster, "initial-cluster", "", "The initial-cluster string the restored member starts with.")
+	flags.StringVar(&advertiseAddress, "advertise-address", "", "The IP address this member advertises to the rest of the cluster.")
+	flags.StringVar(&image, "image", "", "Container image to run etcd from.")
+	flags.StringVar(&manifestDir, "manifest-dir", kubeadmconstants.KubernetesDir+"/"+kubeadmconstants.ManifestsSubDirName, "Directory kubeadm writes static pod manifests to.")
+	return cmd
+}
+
+func newCmdEtcdSnapshotSchedule() *cobra.Command {
+	var scheduleCfg backup.ScheduleConfiguration
+	var manifestDir string
+	var backendName, backendDir, backendBucket, backendPrefix string
+
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Install a static pod that periodically snapshots the local etcd cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backend, err := newBackend(backendName, backendDir, backendBucket, backendPrefix)
+			if err != nil {
+				return err
+			}
+			scheduleCfg.Backend = backend
+			return backup.Schedule(manifestDir, scheduleCfg)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&scheduleCfg.Period, "period", "1h", "How often to take a snapshot.")
+	flags.StringVar(&manifestDir, "manifest-dir", kubeadmconstants.KubernetesDir+"/"+kubeadmconstants.ManifestsSubDirName, "Directory kubeadm writes static pod manifests to.")
+	flags.StringVar(&backendName, "backend", "local", "Where to upload each snapshot: \"local\", \"s3\" or \"gcs\". The \"s3\" and \"gcs\" backends are not yet implemented and will fail at upload time.")
+	flags.StringVar(&backendDir, "backend-dir", "/var/backups/etcd", "Destination directory for the \"local\" backend.")
+	flags.StringVar(&backendBucket, "backend-bucket", "", "Destination bucket for the \"s3\"/\"gcs\" backends.")
+	flags.StringVar(&backendPrefix, "backend-prefix", "", "Object key prefix for the \"s3\"/\"gcs\" backends.")
+	return cmd
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestNewCmdEtcdSubcommandTree(t *testing.T) {
+	cmd := NewCmdEtcd()
+
+	var names []string
+	for _, c := range cmd.Commands() {
+		names = append(names, c.Name())
+	}
+	expectNames(t, names, []string{"snapshot", "join"})
+
+	snapshot := findCommand(t, cmd, "snapshot")
+	var snapshotNames []string
+	for _, c := range snapshot.Commands() {
+		snapshotNames = append(snapshotNames, c.Name())
+	}
+	expectNames(t, snapshotNames, []string{"save", "restore", "schedule"})
+}
+
+func TestNewCmdEtcdJoinFlags(t *testing.T) {
+	cmd := newCmdEtcdJoin()
+
+	if _, err := cmd.Flags().GetString("member-join-mode"); err != nil {
+		t.Errorf("expected a --member-join-mode flag: %v", err)
+	}
+	if _, err := cmd.Flags().GetDuration("etcd-learner-catch-up-timeout"); err != nil {
+		t.Errorf("expected AddLearnerJoinFlags to have registered --etcd-learner-catch-up-timeout: %v", err)
+	}
+	if _, err := cmd.Flags().GetBool("skip-learner-promotion"); err != nil {
+		t.Errorf("expected AddLearnerJoinFlags to have registered --skip-learner-promotion: %v", err)
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	var tests = []struct {
+		name          string
+		backendName   string
+		expectedType  string
+		expectedError bool
+	}{
+		{name: "empty means no backend", backendName: "", expectedType: ""},
+		{name: "local", backendName: "local", expectedType: "*backup.LocalBackend"},
+		{name: "s3", backendName: "s3", expectedType: "*backup.S3Backend"},
+		{name: "gcs", backendName: "gcs", expectedType: "*backup.GCSBackend"},
+		{name: "invalid", backendName: "azure", expectedError: true},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			backend, err := newBackend(rt.backendName, "/tmp", "bucket", "prefix")
+			if rt.expectedError {
+				if err == nil {
+					t.Fatalf("expected newBackend to fail, but it succeeded with: %v", backend)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newBackend failed when not expected: %v", err)
+			}
+			if rt.expectedType == "" {
+				if backend != nil {
+					t.Errorf("expected a nil Backend, got %T", backend)
+				}
+				return
+			}
+			if got := fmt.Sprintf("%T", backend); got != rt.expectedType {
+				t.Errorf("newBackend(%q) type = %s, expected %s", rt.backendName, got, rt.expectedType)
+			}
+		})
+	}
+}
+
+func expectNames(t *testing.T, actual, expected []string) {
+	t.Helper()
+	if len(actual) != len(expected) {
+		t.Fatalf("expected subcommands %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("expected subcommands %v, got %v", expected, actual)
+		}
+	}
+}
+
+func findCommand(t *testing.T, parent *cobra.Command, name string) *cobra.Command {
+	t.Helper()
+	for _, c := range parent.Commands() {
+		if c.Name() == name {
+			return c
+		}
+	}
+	t.Fatalf("expected %q to have a %q subcommand", parent.Name(), name)
+	return nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package constants holds the names and values shared by the kubeadm
+// phases and commands.
+package constants
+
+const (
+	// ManifestsSubDirName defines the directory where static pod manifests are written.
+	ManifestsSubDirName = "manifests"
+
+	// Etcd defines variable used internally when referring to etcd component
+	Etcd = "etcd"
+
+	// KubernetesDir is the directory kubernetes owns for storing various configuration files
+	KubernetesDir = "/etc/kubernetes"
+
+	// EtcdListenClientPort defines the port etcd listens on for client traffic
+	EtcdListenClientPort = 2379
+	// EtcdListenPeerPort defines the port etcd listens on for peer traffic
+	EtcdListenPeerPort = 2380
+
+	// CertificateDir defines the default directory where certificates are stored
+	CertificateDir = KubernetesDir + "/pki"
+
+	// EtcdCACertName defines the etcd CA certificate name
+	EtcdCACertName = "etcd/ca.crt"
+	// EtcdCAKeyName defines the etcd CA key name
+	EtcdCAKeyName = "etcd/ca.key"
+	// EtcdServerCertName defines the etcd server certificate name
+	EtcdServerCertName = "etcd/server.crt"
+	// EtcdServerKeyName defines the etcd server key name
+	EtcdServerKeyName = "etcd/server.key"
+	// EtcdPeerCertName defines the etcd peer certificate name
+	EtcdPeerCertName = "etcd/peer.crt"
+	// EtcdPeerKeyName defines the etcd peer key name
+	EtcdPeerKeyName = "etcd/peer.key"
+	// EtcdHealthcheckClientCertName defines etcd healthcheck client certificate name
+	EtcdHealthcheckClientCertName = "etcd/healthcheck-client.crt"
+	// EtcdHealthcheckClientKeyName defines etcd healthcheck client key name
+	EtcdHealthcheckClientKeyName = "etcd/healthcheck-client.key"
+)
@@ -0,0 +1,91 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Args is a set of etcd command-line flags keyed by flag name (without the leading `--`).
+// It exists so the defaults kubeadm computes and the overrides a user supplies through
+// ExtraArgs can be merged and validated the same way before being flattened into a command.
+type Args map[string]string
+
+// denylistedEtcdArgs are flags that control etcd's security posture or on-disk identity.
+// kubeadm computes these itself from the cluster's PKI and node configuration; letting
+// ExtraArgs silently override them would let a user disable client cert auth, swap in an
+// untrusted CA, or point two members at the same data directory without any warning.
+var denylistedEtcdArgs = []string{
+	"auto-tls",
+	"peer-auto-tls",
+	"client-cert-auth",
+	"peer-client-cert-auth",
+	"cert-file",
+	"key-file",
+	"trusted-ca-file",
+	"peer-cert-file",
+	"peer-key-file",
+	"peer-trusted-ca-file",
+	"data-dir",
+	"name",
+}
+
+// denylistedEtcdProxyArgs are flags that control where and how securely the grpc-proxy static
+// pod talks to the external etcd cluster. Letting ProxyExtraArgs override them would let a
+// user point the proxy at a different backend or CA than the one kubeadm was configured with,
+// silently undermining the proxy's TLS trust.
+var denylistedEtcdProxyArgs = []string{
+	"endpoints",
+	"cacert",
+	"cert",
+	"key",
+	"listen-addr",
+}
+
+// validateExtraArgs returns an error listing every key in extraArgs that is on the
+// denylist. A nil error means extraArgs is safe to merge on top of kubeadm's defaults.
+func validateExtraArgs(extraArgs map[string]string) error {
+	return validateAgainstDenylist(extraArgs, denylistedEtcdArgs, "extraArgs overrides security-critical etcd flags, which is not allowed")
+}
+
+// validateProxyExtraArgs returns an error listing every key in extraArgs that is on the
+// grpc-proxy denylist. A nil error means extraArgs is safe to merge on top of kubeadm's
+// defaults for the etcd grpc-proxy static pod.
+func validateProxyExtraArgs(extraArgs map[string]string) error {
+	return validateAgainstDenylist(extraArgs, denylistedEtcdProxyArgs, "proxyExtraArgs overrides security-critical etcd proxy flags, which is not allowed")
+}
+
+func validateAgainstDenylist(extraArgs map[string]string, denylisted []string, message string) error {
+	denylist := make(map[string]bool, len(denylisted))
+	for _, k := range denylisted {
+		denylist[k] = true
+	}
+
+	var offending []string
+	for k := range extraArgs {
+		if denylist[k] {
+			offending = append(offending, k)
+		}
+	}
+	if len(offending) == 0 {
+		return nil
+	}
+
+	sort.Strings(offending)
+	return fmt.Errorf("%s: %v", message, offending)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// Backend uploads a completed snapshot (the `.db` file plus its Manifest) somewhere durable.
+// `snapshot schedule` is pluggable over Backend so that a cluster can keep snapshots on local
+// disk, or ship them off-box to object storage.
+type Backend interface {
+	// Upload stores snapshotPath (and its accompanying manifest file) under name.
+	Upload(name, snapshotPath, manifestPath string) error
+}
+
+// LocalBackend copies completed snapshots into a directory on the local filesystem. This is
+// the default backend used by `snapshot schedule` when none is configured.
+type LocalBackend struct {
+	// Dir is the directory snapshots are copied into.
+	Dir string
+}
+
+// Upload implements Backend.
+func (b *LocalBackend) Upload(name, snapshotPath, manifestPath string) error {
+	return copyInto(b.Dir, name, snapshotPath, manifestPath)
+}
+
+// S3Backend uploads completed snapshots to an S3-compatible bucket.
+//
+// Not yet implemented: Upload always returns an error. It exists so --backend=s3 is
+// recognized and the rest of the snapshot/schedule plumbing can be exercised ahead of the
+// actual upload support landing.
+type S3Backend struct {
+	// Bucket is the destination bucket name.
+	Bucket string
+	// Prefix is prepended to the object key.
+	Prefix string
+}
+
+// Upload implements Backend.
+func (b *S3Backend) Upload(name, snapshotPath, manifestPath string) error {
+	return fmt.Errorf("S3 backend not yet implemented: would upload %s to s3://%s/%s", snapshotPath, b.Bucket, filepath.Join(b.Prefix, name))
+}
+
+// GCSBackend uploads completed snapshots to a Google Cloud Storage bucket.
+//
+// Not yet implemented: Upload always returns an error. It exists so --backend=gcs is
+// recognized and the rest of the snapshot/schedule plumbing can be exercised ahead of the
+// actual upload support landing.
+type GCSBackend struct {
+	// Bucket is the destination bucket name.
+	Bucket string
+	// Prefix is prepended to the object key.
+	Prefix string
+}
+
+// Upload implements Backend.
+func (b *GCSBackend) Upload(name, snapshotPath, manifestPath string) error {
+	return fmt.Errorf("GCS backend not yet implemented: would upload %s to gs://%s/%s", snapshotPath, b.Bucket, filepath.Join(b.Prefix, name))
+}
+
+// backendFlags renders b as the `--backend*` flags newCmdEtcdSnapshotSave accepts, so that
+// Schedule's generated static pod shells out to `kubeadm etcd snapshot save` with the same
+// backend the schedule itself was configured with.
+func backendFlags(b Backend) string {
+	switch backend := b.(type) {
+	case *LocalBackend:
+		return fmt.Sprintf("--backend=local --backend-dir=%s", backend.Dir)
+	case *S3Backend:
+		return fmt.Sprintf("--backend=s3 --backend-bucket=%s --backend-prefix=%s", backend.Bucket, backend.Prefix)
+	case *GCSBackend:
+		return fmt.Sprintf("--backend=gcs --backend-bucket=%s --backend-prefix=%s", backend.Bucket, backend.Prefix)
+	default:
+		return ""
+	}
+}
+
+func copyInto(dir, name, snapshotPath, manifestPath string) error {
+	data, err := ioutil.ReadFile(snapshotPath)
+	if err != nil {
+		return fmt.Errorf("couldn't read snapshot %s: %v", snapshotPath, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".db"), data, 0600); err != nil {
+		return fmt.Errorf("couldn't copy snapshot into %s: %v", dir, err)
+	}
+
+	manifest, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("couldn't read manifest %s: %v", manifestPath, err)
+	}
+	return ioutil.WriteFile(filepath.Join(dir, name+".json"), manifest, 0600)
+}
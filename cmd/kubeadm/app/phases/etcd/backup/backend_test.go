@@ -0,0 +1,101 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalBackendUpload(t *testing.T) {
+	srcDir, err := ioutil.TempDir("", "kubeadm-test-backend-src")
+	if err != nil {
+		t.Fatalf("couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(srcDir)
+
+	destDir, err := ioutil.TempDir("", "kubeadm-test-backend-dest")
+	if err != nil {
+		t.Fatalf("couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(destDir)
+
+	snapshotPath := filepath.Join(srcDir, "snapshot.db")
+	manifestPath := filepath.Join(srcDir, "snapshot.json")
+	if err := ioutil.WriteFile(snapshotPath, []byte("snapshot-data"), 0600); err != nil {
+		t.Fatalf("couldn't write snapshot fixture: %v", err)
+	}
+	if err := ioutil.WriteFile(manifestPath, []byte("manifest-data"), 0600); err != nil {
+		t.Fatalf("couldn't write manifest fixture: %v", err)
+	}
+
+	backend := &LocalBackend{Dir: destDir}
+	if err := backend.Upload("snapshot-1", snapshotPath, manifestPath); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+
+	gotSnapshot, err := ioutil.ReadFile(filepath.Join(destDir, "snapshot-1.db"))
+	if err != nil {
+		t.Fatalf("couldn't read uploaded snapshot: %v", err)
+	}
+	if string(gotSnapshot) != "snapshot-data" {
+		t.Errorf("uploaded snapshot content = %q, expected %q", gotSnapshot, "snapshot-data")
+	}
+
+	gotManifest, err := ioutil.ReadFile(filepath.Join(destDir, "snapshot-1.json"))
+	if err != nil {
+		t.Fatalf("couldn't read uploaded manifest: %v", err)
+	}
+	if string(gotManifest) != "manifest-data" {
+		t.Errorf("uploaded manifest content = %q, expected %q", gotManifest, "manifest-data")
+	}
+}
+
+func TestBackendFlags(t *testing.T) {
+	var tests = []struct {
+		name     string
+		backend  Backend
+		expected string
+	}{
+		{
+			name:     "local backend",
+			backend:  &LocalBackend{Dir: "/var/backups/etcd"},
+			expected: "--backend=local --backend-dir=/var/backups/etcd",
+		},
+		{
+			name:     "s3 backend",
+			backend:  &S3Backend{Bucket: "my-bucket", Prefix: "etcd"},
+			expected: "--backend=s3 --backend-bucket=my-bucket --backend-prefix=etcd",
+		},
+		{
+			name:     "gcs backend",
+			backend:  &GCSBackend{Bucket: "my-bucket", Prefix: "etcd"},
+			expected: "--backend=gcs --backend-bucket=my-bucket --backend-prefix=etcd",
+		},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			actual := backendFlags(rt.backend)
+			if actual != rt.expected {
+				t.Errorf("backendFlags() = %q, expected %q", actual, rt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	snapshot "go.etcd.io/etcd/etcdctl/snapshot"
+	"go.uber.org/zap"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	etcdphase "k8s.io/kubernetes/cmd/kubeadm/app/phases/etcd"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+// Restore restores dbPath into a fresh etcd data directory described by restoreCfg, then
+// (re)writes the local etcd static pod manifest with --initial-cluster-state=existing so the
+// restored member rejoins as itself rather than bootstrapping a new cluster identity.
+//
+// The static pod is stopped first by moving its manifest out of manifestDir (the kubelet
+// tears down the pod once the manifest disappears). If anything after that point fails, the
+// parked manifest is moved back so the node is left with the static pod it started with
+// instead of none at all; it is only discarded once the restore and the rewritten manifest
+// have both succeeded.
+func Restore(cfg *kubeadmapi.InitConfiguration, restoreCfg RestoreConfiguration, dbPath, manifestDir string) error {
+	manifestPath := filepath.Join(manifestDir, kubeadmconstants.Etcd+".yaml")
+	parkedPath := manifestPath + ".bak"
+
+	stopped, err := stopStaticPod(manifestPath, parkedPath)
+	if err != nil {
+		return err
+	}
+
+	if err := restore(cfg, restoreCfg, dbPath, manifestDir); err != nil {
+		if stopped {
+			if restoreErr := os.Rename(parkedPath, manifestPath); restoreErr != nil {
+				return fmt.Errorf("%v (additionally, couldn't restore the original etcd static pod manifest: %v)", err, restoreErr)
+			}
+		}
+		return err
+	}
+
+	if stopped {
+		return os.Remove(parkedPath)
+	}
+	return nil
+}
+
+// restore does the actual snapshot restore and manifest rewrite, leaving the parked manifest's
+// fate to its caller.
+func restore(cfg *kubeadmapi.InitConfiguration, restoreCfg RestoreConfiguration, dbPath, manifestDir string) error {
+	sm := snapshot.NewV3(zap.NewNop())
+	if err := sm.Restore(snapshot.RestoreConfig{
+		SnapshotPath:        dbPath,
+		Name:                restoreCfg.Name,
+		OutputDataDir:       restoreCfg.DataDir,
+		PeerURLs:            restoreCfg.InitialAdvertisePeerURLs,
+		InitialCluster:      restoreCfg.InitialCluster,
+		InitialClusterToken: "etcd-cluster",
+	}); err != nil {
+		return fmt.Errorf("couldn't restore etcd snapshot: %v", err)
+	}
+
+	restored := []etcdutil.Member{{Name: restoreCfg.Name, PeerURL: firstOrEmpty(restoreCfg.InitialAdvertisePeerURLs)}}
+	return etcdphase.CreateLocalEtcdStaticPodManifestFileWithJoin(manifestDir, cfg, restored, etcdphase.DefaultLearnerJoinConfiguration())
+}
+
+// stopStaticPod moves the etcd static pod manifest out of the kubelet's watched directory so
+// the kubelet tears the pod down, freeing the data dir for the restore. It returns false (and
+// does nothing) if there was no manifest to stop, e.g. when restoring before the node has
+// ever run etcd.
+func stopStaticPod(manifestPath, parkedPath string) (bool, error) {
+	if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
+		return false, nil
+	}
+	if err := os.Rename(manifestPath, parkedPath); err != nil {
+		return false, fmt.Errorf("couldn't stop etcd static pod: %v", err)
+	}
+	return true, nil
+}
+
+func firstOrEmpty(urls []string) string {
+	if len(urls) == 0 {
+		return ""
+	}
+	return urls[0]
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStopStaticPod(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeadm-test-stop-static-pod")
+	if err != nil {
+		t.Fatalf("couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifestPath := filepath.Join(dir, "etcd.yaml")
+	parkedPath := manifestPath + ".bak"
+
+	t.Run("no manifest to stop", func(t *testing.T) {
+		stopped, err := stopStaticPod(manifestPath, parkedPath)
+		if err != nil {
+			t.Fatalf("stopStaticPod failed: %v", err)
+		}
+		if stopped {
+			t.Errorf("expected stopped = false when there is no manifest, got true")
+		}
+	})
+
+	if err := ioutil.WriteFile(manifestPath, []byte("pod"), 0600); err != nil {
+		t.Fatalf("couldn't write manifest fixture: %v", err)
+	}
+
+	t.Run("manifest is parked out of the way", func(t *testing.T) {
+		stopped, err := stopStaticPod(manifestPath, parkedPath)
+		if err != nil {
+			t.Fatalf("stopStaticPod failed: %v", err)
+		}
+		if !stopped {
+			t.Errorf("expected stopped = true, got false")
+		}
+		if _, err := os.Stat(manifestPath); !os.IsNotExist(err) {
+			t.Errorf("expected %s to no longer exist", manifestPath)
+		}
+		if _, err := os.Stat(parkedPath); err != nil {
+			t.Errorf("expected %s to exist: %v", parkedPath, err)
+		}
+	})
+}
+
+func TestFirstOrEmpty(t *testing.T) {
+	var tests = []struct {
+		name     string
+		urls     []string
+		expected string
+	}{
+		{name: "nil slice", urls: nil, expected: ""},
+		{name: "empty slice", urls: []string{}, expected: ""},
+		{name: "single url", urls: []string{"https://1.2.3.4:2380"}, expected: "https://1.2.3.4:2380"},
+		{name: "multiple urls returns the first", urls: []string{"https://1.2.3.4:2380", "https://5.6.7.8:2380"}, expected: "https://1.2.3.4:2380"},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			actual := firstOrEmpty(rt.urls)
+			if actual != rt.expected {
+				t.Errorf("firstOrEmpty(%v) = %q, expected %q", rt.urls, actual, rt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/clientv3"
+	snapshot "go.etcd.io/etcd/etcdctl/snapshot"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+const snapshotTimeout = 1 * time.Minute
+
+// Save writes a point-in-time snapshot of the local etcd that
+// etcd.CreateLocalEtcdStaticPodManifestFile manages to dbPath, and a companion Manifest to
+// manifestPath. It uses the embedded etcd v3 client with the same TLS material kubeadm
+// renders for the static pod (kubeadmconstants.CertificateDir / EtcdCACertName / EtcdServerCertName /
+// EtcdServerKeyName), so it works whether or not the kube-apiserver is up.
+//
+// If backend is non-nil, the snapshot and manifest are additionally handed to
+// backend.Upload once both have been written successfully.
+func Save(cfg *kubeadmapi.InitConfiguration, endpoint, dbPath, manifestPath string, backend Backend) error {
+	client, err := etcdutil.NewClientFromInitConfiguration(cfg, []etcdutil.Member{{ClientURL: endpoint}})
+	if err != nil {
+		return fmt.Errorf("couldn't create etcd client: %v", err)
+	}
+	defer client.Close()
+
+	members, err := client.MemberList()
+	if err != nil {
+		return fmt.Errorf("couldn't list etcd members: %v", err)
+	}
+
+	status, err := client.Status()
+	if err != nil {
+		return fmt.Errorf("couldn't fetch etcd cluster status: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+	if err := snapshot.NewV3(nil).Save(ctx, clientv3.Config{Endpoints: []string{endpoint}}, dbPath); err != nil {
+		return fmt.Errorf("couldn't save etcd snapshot: %v", err)
+	}
+
+	sum, err := sha256File(dbPath)
+	if err != nil {
+		return fmt.Errorf("couldn't checksum etcd snapshot: %v", err)
+	}
+
+	manifest := Manifest{
+		ClusterID: status.ClusterID,
+		Revision:  status.Revision,
+		Members:   members,
+		SHA256:    sum,
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't marshal snapshot manifest: %v", err)
+	}
+	if err := ioutil.WriteFile(manifestPath, data, 0600); err != nil {
+		return err
+	}
+
+	if backend == nil {
+		return nil
+	}
+	name := strings.TrimSuffix(filepath.Base(dbPath), filepath.Ext(dbPath))
+	if err := backend.Upload(name, dbPath, manifestPath); err != nil {
+		return fmt.Errorf("couldn't upload snapshot: %v", err)
+	}
+	return nil
+}
+
+func sha256File(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSha256File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeadm-test-sha256")
+	if err != nil {
+		t.Fatalf("couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "snapshot.db")
+	if err := ioutil.WriteFile(path, []byte("hello"), 0600); err != nil {
+		t.Fatalf("couldn't write fixture: %v", err)
+	}
+
+	sum, err := sha256File(path)
+	if err != nil {
+		t.Fatalf("sha256File failed: %v", err)
+	}
+	// sha256("hello")
+	expected := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if sum != expected {
+		t.Errorf("sha256File() = %q, expected %q", sum, expected)
+	}
+
+	if _, err := sha256File(filepath.Join(dir, "does-not-exist")); err == nil {
+		t.Errorf("expected sha256File to fail for a missing file, but it succeeded")
+	}
+}
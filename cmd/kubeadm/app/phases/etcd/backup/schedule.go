@@ -0,0 +1,71 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// scheduleManifestName is the static pod manifest filename `snapshot schedule` writes. It is
+// intentionally distinct from kubeadmconstants.Etcd+".yaml" so it isn't mistaken for (or
+// clobbered by) the etcd server's own manifest.
+const scheduleManifestName = "etcd-snapshot-schedule.yaml"
+
+// Schedule installs a static pod that periodically runs `kubeadm etcd snapshot save` against
+// the local etcd instance and uploads each result through cfg.Backend, which defaults to a
+// LocalBackend writing to /var/backups/etcd when cfg.Backend is nil. There is no
+// CronJob controller available to a control-plane-only static pod, so periodicity is
+// implemented as a simple sleep loop inside the pod's command.
+func Schedule(manifestDir string, cfg ScheduleConfiguration) error {
+	if cfg.Backend == nil {
+		cfg.Backend = &LocalBackend{Dir: "/var/backups/etcd"}
+	}
+
+	command := []string{
+		"sh", "-c",
+		fmt.Sprintf("while true; do kubeadm etcd snapshot save --output /var/backups/etcd/snapshot-$(date +%%s).db %s; sleep %s; done", backendFlags(cfg.Backend), cfg.Period),
+	}
+
+	pod := &v1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "etcd-snapshot-schedule",
+			Namespace: "kube-system",
+		},
+		Spec: v1.PodSpec{
+			HostNetwork: true,
+			Containers: []v1.Container{
+				{
+					Name:    "etcd-snapshot-schedule",
+					Command: command,
+				},
+			},
+		},
+	}
+
+	data, err := yaml.Marshal(pod)
+	if err != nil {
+		return fmt.Errorf("failed to marshal etcd snapshot schedule manifest: %v", err)
+	}
+	return ioutil.WriteFile(filepath.Join(manifestDir, scheduleManifestName), data, 0600)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchedule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeadm-test-schedule")
+	if err != nil {
+		t.Fatalf("couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := Schedule(dir, ScheduleConfiguration{Period: "30m", Backend: &S3Backend{Bucket: "my-bucket", Prefix: "etcd"}}); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, scheduleManifestName))
+	if err != nil {
+		t.Fatalf("couldn't read schedule manifest: %v", err)
+	}
+
+	manifest := string(data)
+	if !strings.Contains(manifest, "sleep 30m") {
+		t.Errorf("expected manifest to shell out with the configured period, got:\n%s", manifest)
+	}
+	if !strings.Contains(manifest, "--backend=s3 --backend-bucket=my-bucket --backend-prefix=etcd") {
+		t.Errorf("expected manifest to pass the configured backend through to the save command, got:\n%s", manifest)
+	}
+	if !strings.Contains(manifest, "snapshot save --output /var/backups/etcd/snapshot-$(date +%s).db") {
+		t.Errorf("expected manifest to pass a distinct, timestamped --output path to each run, got:\n%s", manifest)
+	}
+}
+
+func TestScheduleDefaultsToLocalBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeadm-test-schedule-default")
+	if err != nil {
+		t.Fatalf("couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := Schedule(dir, ScheduleConfiguration{Period: "1h"}); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, scheduleManifestName))
+	if err != nil {
+		t.Fatalf("couldn't read schedule manifest: %v", err)
+	}
+
+	if !strings.Contains(string(data), "--backend=local --backend-dir=/var/backups/etcd") {
+		t.Errorf("expected manifest to default to the local backend, got:\n%s", string(data))
+	}
+}
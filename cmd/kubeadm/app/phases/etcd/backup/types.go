@@ -0,0 +1,60 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backup implements `kubeadm etcd snapshot save/restore/schedule`, which operate on
+// the local etcd instance that the etcd phase's CreateLocalEtcdStaticPodManifestFile manages.
+package backup
+
+import (
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+// Manifest describes a snapshot taken by Save: what cluster it came from, at what revision,
+// who the members were at the time, and the checksum of the snapshot file. Restore does not
+// read it.
+type Manifest struct {
+	// ClusterID is the etcd cluster ID the snapshot was taken from.
+	ClusterID uint64 `json:"clusterID"`
+	// Revision is the etcd store revision at the time the snapshot was taken.
+	Revision int64 `json:"revision"`
+	// Members lists the cluster members known at snapshot time.
+	Members []etcdutil.Member `json:"members"`
+	// SHA256 is the checksum of the accompanying `.db` snapshot file.
+	SHA256 string `json:"sha256"`
+}
+
+// RestoreConfiguration mirrors kubeadmapi.LocalEtcd for the purposes of restoring a snapshot:
+// it lets a user restore into a different DataDir or under a different member name than the
+// cluster the snapshot was taken from.
+type RestoreConfiguration struct {
+	// Name is the member name the restored etcd instance will run as.
+	Name string
+	// DataDir is the directory the restored data will be written to.
+	DataDir string
+	// InitialAdvertisePeerURLs is the peer URL the restored member advertises.
+	InitialAdvertisePeerURLs []string
+	// InitialCluster is the initial-cluster string the restored member starts with.
+	InitialCluster string
+}
+
+// ScheduleConfiguration configures the `snapshot schedule` static pod.
+type ScheduleConfiguration struct {
+	// Period is how often a snapshot is taken.
+	Period string
+	// Backend uploads completed snapshots somewhere durable. Defaults to keeping the
+	// snapshot on local disk when nil.
+	Backend Backend
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddLearnerJoinFlags adds the `kubeadm etcd join` flags that control how a new local etcd
+// member joining in learner mode waits to catch up and gets promoted.
+func AddLearnerJoinFlags(flagSet *pflag.FlagSet, cfg *LearnerJoinConfiguration) {
+	flagSet.DurationVar(&cfg.Timeout, "etcd-learner-catch-up-timeout", cfg.Timeout,
+		"The amount of time to wait for a new etcd member joining as a learner to catch up with the cluster leader before kubeadm gives up.")
+	flagSet.DurationVar(&cfg.RetryInterval, "etcd-learner-poll-interval", cfg.RetryInterval,
+		"How often kubeadm polls a joining learner's raft applied index while waiting for it to catch up.")
+	flagSet.Uint64Var(&cfg.MaxRaftIndexLag, "etcd-learner-max-raft-index-lag", cfg.MaxRaftIndexLag,
+		"The maximum number of raft log entries a joining learner may lag behind the leader's committed index before kubeadm promotes it to a voting member.")
+	flagSet.BoolVar(&cfg.SkipPromotion, "skip-learner-promotion", cfg.SkipPromotion,
+		"Add the new etcd member as a learner but skip the automatic promotion to voting member once it has caught up. The member must be promoted out of band.")
+}
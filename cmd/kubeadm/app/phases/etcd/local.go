@@ -0,0 +1,382 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package etcd deals with the local etcd static pod that kubeadm manages for the
+// control-plane nodes of a cluster.
+package etcd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+// defaultLearnerMaxRaftIndexLag is how far behind the leader's committed index a learner's
+// RaftAppliedIndex is allowed to be before kubeadm considers it caught up and promotes it.
+const defaultLearnerMaxRaftIndexLag = 5000
+
+// LearnerJoinConfiguration controls how a new local etcd member joins an existing cluster
+// when kubeadmapi.LocalEtcd.MemberJoinMode is MemberJoinLearner.
+type LearnerJoinConfiguration struct {
+	// MaxRaftIndexLag is the maximum number of raft log entries the joining learner may be
+	// behind the leader's committed index before it is promoted to a voting member.
+	MaxRaftIndexLag uint64
+	// Timeout bounds how long kubeadm waits for the learner to catch up before giving up.
+	Timeout time.Duration
+	// RetryInterval is how often kubeadm polls the learner's status while waiting for it
+	// to catch up.
+	RetryInterval time.Duration
+	// SkipPromotion disables the automatic MemberPromote call; the member is left as a
+	// learner and must be promoted out of band. This is the `--skip-learner-promotion`
+	// escape hatch.
+	SkipPromotion bool
+}
+
+// DefaultLearnerJoinConfiguration returns the LearnerJoinConfiguration kubeadm uses when the
+// user hasn't overridden any of the join phase flags.
+func DefaultLearnerJoinConfiguration() LearnerJoinConfiguration {
+	return LearnerJoinConfiguration{
+		MaxRaftIndexLag: defaultLearnerMaxRaftIndexLag,
+		Timeout:         5 * time.Minute,
+		RetryInterval:   2 * time.Second,
+	}
+}
+
+// CreateLocalEtcdStaticPodManifestFile will write local etcd static pod manifest file.
+// This function is used by kubeadm init, and by kubeadm upgrade.
+func CreateLocalEtcdStaticPodManifestFile(manifestDir string, cfg *kubeadmapi.InitConfiguration) error {
+	return createLocalEtcdStaticPodManifestFile(manifestDir, cfg, nil, DefaultLearnerJoinConfiguration())
+}
+
+// CreateLocalEtcdStaticPodManifestFileWithJoin behaves like CreateLocalEtcdStaticPodManifestFile
+// but additionally handles joining an existing cluster described by initialCluster. When the
+// local member's MemberJoinMode is MemberJoinLearner, the member is added as a non-voting
+// learner and promoted once it has caught up, rather than added directly as a voter.
+func CreateLocalEtcdStaticPodManifestFileWithJoin(manifestDir string, cfg *kubeadmapi.InitConfiguration, initialCluster []etcdutil.Member, joinCfg LearnerJoinConfiguration) error {
+	return createLocalEtcdStaticPodManifestFile(manifestDir, cfg, initialCluster, joinCfg)
+}
+
+func createLocalEtcdStaticPodManifestFile(manifestDir string, cfg *kubeadmapi.InitConfiguration, initialCluster []etcdutil.Member, joinCfg LearnerJoinConfiguration) error {
+	if cfg.Etcd.Local == nil {
+		if cfg.Etcd.External != nil && cfg.Etcd.External.EnableProxy {
+			return CreateExternalEtcdProxyStaticPodManifestFile(manifestDir, cfg.Etcd.External)
+		}
+		return fmt.Errorf("etcd static pod manifest cannot be generated for cluster using external etcd")
+	}
+
+	// Joining an already-running cluster as a learner is a multi-step dance: the new member
+	// must be registered with the cluster *before* its own etcd process starts, because etcd
+	// refuses to start a member that isn't part of the cluster it's told to join. The catch-up
+	// wait, on the other hand, has to happen *after* the static pod manifest below is written
+	// and the new member's own etcd process is actually up — nothing answers on its client
+	// endpoint before that.
+	learnerJoin := len(initialCluster) > 0 && cfg.Etcd.Local.MemberJoinMode == kubeadmapi.MemberJoinLearner
+
+	var client etcdutil.ClusterClient
+	if learnerJoin {
+		c, err := addLocalEtcdAsLearner(cfg, initialCluster)
+		if err != nil {
+			return err
+		}
+		client = c
+		defer client.Close()
+	}
+
+	spec, err := GetEtcdPodSpec(cfg, initialCluster)
+	if err != nil {
+		return err
+	}
+	pod := &v1.Pod{
+		TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: spec.ObjectMeta,
+		Spec:       spec.Spec,
+	}
+
+	data, err := yaml.Marshal(pod)
+	if err != nil {
+		return fmt.Errorf("failed to marshal etcd static pod manifest: %v", err)
+	}
+
+	if err := os.MkdirAll(manifestDir, 0700); err != nil {
+		return fmt.Errorf("failed to create manifest directory %q: %v", manifestDir, err)
+	}
+
+	manifestPath := filepath.Join(manifestDir, kubeadmconstants.Etcd+".yaml")
+	if err := ioutil.WriteFile(manifestPath, data, 0600); err != nil {
+		return err
+	}
+
+	if learnerJoin && !joinCfg.SkipPromotion {
+		return waitForLearnerAndPromote(client, cfg.NodeRegistration.Name, joinCfg)
+	}
+	return nil
+}
+
+// addLocalEtcdAsLearner registers the local member with initialCluster as a non-voting
+// learner, ahead of the local etcd process even starting. It returns the client used to do so,
+// which the caller keeps open to later wait for catch-up and promote with.
+func addLocalEtcdAsLearner(cfg *kubeadmapi.InitConfiguration, initialCluster []etcdutil.Member) (etcdutil.ClusterClient, error) {
+	name := cfg.NodeRegistration.Name
+	peerURL := fmt.Sprintf("https://%s:%d", cfg.APIEndpoint.AdvertiseAddress, kubeadmconstants.EtcdListenPeerPort)
+
+	client, err := etcdutil.NewClientFromInitConfiguration(cfg, initialCluster)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create etcd client to add learner %q: %v", name, err)
+	}
+
+	if err := client.MemberAddAsLearner(peerURL); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("couldn't add %q as an etcd learner: %v", name, err)
+	}
+
+	return client, nil
+}
+
+// waitForLearnerAndPromote polls the learner's RaftAppliedIndex until it is within
+// joinCfg.MaxRaftIndexLag entries of the leader's committed index, then promotes it to a
+// voting member. Polling errors are tolerated rather than treated as fatal: right after the
+// static pod manifest is written, the learner's own etcd process may still be starting up, so
+// its client endpoint can refuse connections for the first few poll intervals. waitForLearnerAndPromote
+// gives up and returns an error (the last polling error, if any) after joinCfg.Timeout, leaving
+// the member as a learner so a future retry (or --skip-learner-promotion) can pick up where
+// this left off.
+func waitForLearnerAndPromote(client etcdutil.ClusterClient, name string, joinCfg LearnerJoinConfiguration) error {
+	deadline := time.Now().Add(joinCfg.Timeout)
+	var lastErr error
+	for {
+		if caughtUp, err := pollLearnerCaughtUp(client, name, joinCfg.MaxRaftIndexLag); err != nil {
+			lastErr = err
+		} else if caughtUp {
+			return client.MemberPromote(name)
+		}
+
+		if time.Now().After(deadline) {
+			if lastErr != nil {
+				return fmt.Errorf("timed out after %s waiting for learner %q to catch up with the leader: %v", joinCfg.Timeout, name, lastErr)
+			}
+			return fmt.Errorf("timed out after %s waiting for learner %q to catch up with the leader", joinCfg.Timeout, name)
+		}
+		time.Sleep(joinCfg.RetryInterval)
+	}
+}
+
+// pollLearnerCaughtUp lists the cluster's members and reports whether the named learner has
+// caught up, per learnerCaughtUp.
+func pollLearnerCaughtUp(client etcdutil.ClusterClient, name string, maxLag uint64) (bool, error) {
+	members, err := client.MemberList()
+	if err != nil {
+		return false, fmt.Errorf("couldn't list etcd members while waiting for learner %q to catch up: %v", name, err)
+	}
+	return learnerCaughtUp(client, members, name, maxLag)
+}
+
+// learnerCaughtUp compares the named learner's RaftAppliedIndex against the leader's
+// committed index and returns whether it is within maxLag entries.
+func learnerCaughtUp(client etcdutil.ClusterClient, members []etcdutil.Member, name string, maxLag uint64) (bool, error) {
+	status, err := client.Status()
+	if err != nil {
+		return false, fmt.Errorf("couldn't fetch etcd cluster status: %v", err)
+	}
+
+	for _, m := range members {
+		if m.Name != name {
+			continue
+		}
+		applied, err := client.RaftAppliedIndex(m)
+		if err != nil {
+			return false, fmt.Errorf("couldn't fetch raft applied index for learner %q: %v", name, err)
+		}
+		if status.RaftIndex < applied {
+			return true, nil
+		}
+		return status.RaftIndex-applied <= maxLag, nil
+	}
+	return false, fmt.Errorf("learner %q not found in etcd member list", name)
+}
+
+// GetEtcdPodSpec returns the Spec for the etcd pod given the configuration.
+// This function is used by CreateLocalEtcdStaticPodManifestFile.
+func GetEtcdPodSpec(cfg *kubeadmapi.InitConfiguration, initialCluster []etcdutil.Member) (v1.Pod, error) {
+	command, err := getEtcdCommand(cfg, initialCluster)
+	if err != nil {
+		return v1.Pod{}, err
+	}
+
+	pathType := v1.HostPathDirectoryOrCreate
+	etcdMounts := []v1.Volume{
+		{
+			Name: "etcd-data",
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{Path: cfg.Etcd.Local.DataDir, Type: &pathType},
+			},
+		},
+		{
+			Name: "etcd-certs",
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{Path: filepath.Join(kubeadmconstants.CertificateDir, "etcd"), Type: &pathType},
+			},
+		},
+	}
+
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubeadmconstants.Etcd,
+			Namespace: "kube-system",
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:    kubeadmconstants.Etcd,
+					Command: command,
+					Image:   cfg.Etcd.Local.Image,
+					Ports:   additionalURLHostPorts(cfg),
+				},
+			},
+			Volumes:     etcdMounts,
+			HostNetwork: true,
+		},
+	}, nil
+}
+
+// additionalURLHostPorts returns a ContainerPort entry for every port named by
+// AdditionalPeerURLs/AdditionalClientURLs, so kube-proxy and the CNI plugin can see (and thus
+// route to) the extra addresses a member advertises during a live control-plane migration.
+func additionalURLHostPorts(cfg *kubeadmapi.InitConfiguration) []v1.ContainerPort {
+	var ports []v1.ContainerPort
+	for _, url := range cfg.Etcd.Local.AdditionalPeerURLs {
+		if port, ok := portFromURL(url); ok {
+			ports = append(ports, v1.ContainerPort{Name: "peer-additional", HostPort: port, ContainerPort: port})
+		}
+	}
+	for _, url := range cfg.Etcd.Local.AdditionalClientURLs {
+		if port, ok := portFromURL(url); ok {
+			ports = append(ports, v1.ContainerPort{Name: "client-additional", HostPort: port, ContainerPort: port})
+		}
+	}
+	return ports
+}
+
+// portFromURL extracts the numeric port from a "scheme://host:port" URL.
+func portFromURL(rawURL string) (int32, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, false
+	}
+	port, err := strconv.ParseInt(u.Port(), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(port), true
+}
+
+// getEtcdCommand builds the right etcd command from the given config object. It refuses to
+// build a command (and returns an error instead) if ExtraArgs attempts to override one of the
+// security-critical flags on the denylist.
+func getEtcdCommand(cfg *kubeadmapi.InitConfiguration, initialCluster []etcdutil.Member) ([]string, error) {
+	if err := validateExtraArgs(cfg.Etcd.Local.ExtraArgs); err != nil {
+		return nil, err
+	}
+
+	peerPort := kubeadmconstants.EtcdListenPeerPort
+	clientPort := kubeadmconstants.EtcdListenClientPort
+
+	advertiseClientURL := fmt.Sprintf("https://%s:%d", cfg.APIEndpoint.AdvertiseAddress, clientPort)
+	listenPeerURL := fmt.Sprintf("https://%s:%d", cfg.APIEndpoint.AdvertiseAddress, peerPort)
+
+	clientURLs := dedupeURLs(append([]string{advertiseClientURL}, cfg.Etcd.Local.AdditionalClientURLs...))
+	peerURLs := dedupeURLs(append([]string{listenPeerURL}, cfg.Etcd.Local.AdditionalPeerURLs...))
+
+	defaultArgs := Args{
+		"name":                        cfg.NodeRegistration.Name,
+		"listen-client-urls":          fmt.Sprintf("https://127.0.0.1:%d,%s", clientPort, strings.Join(clientURLs, ",")),
+		"advertise-client-urls":       strings.Join(clientURLs, ","),
+		"listen-peer-urls":            strings.Join(peerURLs, ","),
+		"initial-advertise-peer-urls": strings.Join(peerURLs, ","),
+		"data-dir":                    cfg.Etcd.Local.DataDir,
+		"cert-file":                   kubeadmconstants.EtcdServerCertName,
+		"key-file":                    kubeadmconstants.EtcdServerKeyName,
+		"trusted-ca-file":             kubeadmconstants.EtcdCACertName,
+		"client-cert-auth":            "true",
+		"peer-cert-file":              kubeadmconstants.EtcdPeerCertName,
+		"peer-key-file":               kubeadmconstants.EtcdPeerKeyName,
+		"peer-trusted-ca-file":        kubeadmconstants.EtcdCACertName,
+		"peer-client-cert-auth":       "true",
+		"snapshot-count":              "10000",
+	}
+
+	command := []string{"etcd"}
+
+	if len(initialCluster) > 0 {
+		members := make([]string, 0, len(initialCluster))
+		for _, m := range initialCluster {
+			for _, url := range m.AllPeerURLs() {
+				members = append(members, fmt.Sprintf("%s=%s", m.Name, url))
+			}
+		}
+		defaultArgs["initial-cluster"] = strings.Join(members, ",")
+		defaultArgs["initial-cluster-state"] = "existing"
+	} else {
+		members := make([]string, 0, len(peerURLs))
+		for _, url := range peerURLs {
+			members = append(members, fmt.Sprintf("%s=%s", cfg.NodeRegistration.Name, url))
+		}
+		defaultArgs["initial-cluster"] = strings.Join(members, ",")
+	}
+
+	for k, v := range cfg.Etcd.Local.ExtraArgs {
+		defaultArgs[k] = v
+	}
+
+	keys := make([]string, 0, len(defaultArgs))
+	for k := range defaultArgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		command = append(command, fmt.Sprintf("--%s=%s", k, defaultArgs[k]))
+	}
+
+	return command, nil
+}
+
+// dedupeURLs returns urls with empty entries and duplicates removed, preserving order.
+func dedupeURLs(urls []string) []string {
+	seen := make(map[string]bool, len(urls))
+	unique := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		unique = append(unique, u)
+	}
+	return unique
+}
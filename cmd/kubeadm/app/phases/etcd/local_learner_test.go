@@ -0,0 +1,182 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+// stubClusterClient is a minimal etcdutil.ClusterClient double so the learner catch-up/promote
+// logic can be tested without a real etcd cluster.
+type stubClusterClient struct {
+	members        []etcdutil.Member
+	raftIndex      uint64
+	appliedIndexes map[string]uint64
+	promoted       string
+	statusErr      error
+	// statusErrCount, if positive, makes Status fail that many times (simulating a learner
+	// whose client endpoint isn't answering yet) before it starts succeeding.
+	statusErrCount int
+}
+
+func (s *stubClusterClient) MemberAddAsLearner(peerURL string) error { return nil }
+
+func (s *stubClusterClient) MemberList() ([]etcdutil.Member, error) {
+	return s.members, nil
+}
+
+func (s *stubClusterClient) MemberPromote(name string) error {
+	s.promoted = name
+	return nil
+}
+
+func (s *stubClusterClient) Status() (*etcdutil.ClusterStatus, error) {
+	if s.statusErr != nil {
+		return nil, s.statusErr
+	}
+	if s.statusErrCount > 0 {
+		s.statusErrCount--
+		return nil, fmt.Errorf("dial tcp: connection refused")
+	}
+	return &etcdutil.ClusterStatus{RaftIndex: s.raftIndex}, nil
+}
+
+func (s *stubClusterClient) RaftAppliedIndex(member etcdutil.Member) (uint64, error) {
+	return s.appliedIndexes[member.Name], nil
+}
+
+func (s *stubClusterClient) Close() error { return nil }
+
+func TestLearnerCaughtUp(t *testing.T) {
+	members := []etcdutil.Member{{Name: "learner"}}
+
+	var tests = []struct {
+		name             string
+		client           *stubClusterClient
+		maxLag           uint64
+		expectedCaughtUp bool
+		expectedError    bool
+	}{
+		{
+			name:             "within max lag",
+			client:           &stubClusterClient{raftIndex: 100, appliedIndexes: map[string]uint64{"learner": 99}},
+			maxLag:           5,
+			expectedCaughtUp: true,
+		},
+		{
+			name:             "beyond max lag",
+			client:           &stubClusterClient{raftIndex: 100, appliedIndexes: map[string]uint64{"learner": 50}},
+			maxLag:           5,
+			expectedCaughtUp: false,
+		},
+		{
+			name:             "applied index ahead of leader's (just caught up)",
+			client:           &stubClusterClient{raftIndex: 100, appliedIndexes: map[string]uint64{"learner": 101}},
+			maxLag:           5,
+			expectedCaughtUp: true,
+		},
+		{
+			name:          "status call fails",
+			client:        &stubClusterClient{statusErr: fmt.Errorf("connection refused")},
+			maxLag:        5,
+			expectedError: true,
+		},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			caughtUp, err := learnerCaughtUp(rt.client, members, "learner", rt.maxLag)
+			if rt.expectedError {
+				if err == nil {
+					t.Fatalf("expected learnerCaughtUp to fail, but it succeeded with: %v", caughtUp)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("learnerCaughtUp failed when not expected: %v", err)
+			}
+			if caughtUp != rt.expectedCaughtUp {
+				t.Errorf("learnerCaughtUp() = %v, expected %v", caughtUp, rt.expectedCaughtUp)
+			}
+		})
+	}
+
+	if _, err := learnerCaughtUp(&stubClusterClient{raftIndex: 100}, members, "missing", 5); err == nil {
+		t.Errorf("expected learnerCaughtUp to fail for a member not in the member list, but it succeeded")
+	}
+}
+
+func TestWaitForLearnerAndPromote(t *testing.T) {
+	client := &stubClusterClient{
+		members:        []etcdutil.Member{{Name: "learner"}},
+		raftIndex:      100,
+		appliedIndexes: map[string]uint64{"learner": 99},
+	}
+	joinCfg := DefaultLearnerJoinConfiguration()
+
+	if err := waitForLearnerAndPromote(client, "learner", joinCfg); err != nil {
+		t.Fatalf("waitForLearnerAndPromote failed: %v", err)
+	}
+	if client.promoted != "learner" {
+		t.Errorf("expected learner %q to have been promoted, got %q", "learner", client.promoted)
+	}
+}
+
+// TestWaitForLearnerAndPromoteToleratesTransientErrors covers the window right after the
+// learner's static pod manifest is written, where its client endpoint may still refuse
+// connections for the first few polls: waitForLearnerAndPromote must keep retrying rather
+// than give up on the first error.
+func TestWaitForLearnerAndPromoteToleratesTransientErrors(t *testing.T) {
+	client := &stubClusterClient{
+		members:        []etcdutil.Member{{Name: "learner"}},
+		raftIndex:      100,
+		appliedIndexes: map[string]uint64{"learner": 99},
+		statusErrCount: 3,
+	}
+	joinCfg := DefaultLearnerJoinConfiguration()
+	joinCfg.RetryInterval = time.Millisecond
+
+	if err := waitForLearnerAndPromote(client, "learner", joinCfg); err != nil {
+		t.Fatalf("waitForLearnerAndPromote failed: %v", err)
+	}
+	if client.promoted != "learner" {
+		t.Errorf("expected learner %q to have been promoted, got %q", "learner", client.promoted)
+	}
+}
+
+// TestWaitForLearnerAndPromoteTimesOutWithLastError covers the case where the learner's
+// endpoint never comes up: waitForLearnerAndPromote should time out rather than retry forever,
+// and the returned error should surface the last polling failure.
+func TestWaitForLearnerAndPromoteTimesOutWithLastError(t *testing.T) {
+	client := &stubClusterClient{statusErr: fmt.Errorf("dial tcp: connection refused")}
+	joinCfg := DefaultLearnerJoinConfiguration()
+	joinCfg.Timeout = 10 * time.Millisecond
+	joinCfg.RetryInterval = time.Millisecond
+
+	err := waitForLearnerAndPromote(client, "learner", joinCfg)
+	if err == nil {
+		t.Fatalf("expected waitForLearnerAndPromote to time out, but it succeeded")
+	}
+	if !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected timeout error to include the last polling error, got: %v", err)
+	}
+}
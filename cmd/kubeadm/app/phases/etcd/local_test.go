@@ -24,6 +24,8 @@ import (
 	"sort"
 	"testing"
 
+	v1 "k8s.io/api/core/v1"
+
 	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
 	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
 	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
@@ -45,7 +47,10 @@ func TestGetEtcdPodSpec(t *testing.T) {
 	}
 
 	// Executes GetEtcdPodSpec
-	spec := GetEtcdPodSpec(cfg, []etcdutil.Member{})
+	spec, err := GetEtcdPodSpec(cfg, []etcdutil.Member{})
+	if err != nil {
+		t.Fatalf("GetEtcdPodSpec failed: %v", err)
+	}
 
 	// Assert each specs refers to the right pod
 	if spec.Spec.Containers[0].Name != kubeadmconstants.Etcd {
@@ -54,13 +59,10 @@ func TestGetEtcdPodSpec(t *testing.T) {
 }
 
 func TestCreateLocalEtcdStaticPodManifestFile(t *testing.T) {
-	// Create temp folder for the test case
-	tmpdir := testutil.SetupTempDir(t)
-	defer os.RemoveAll(tmpdir)
-
 	var tests = []struct {
 		cfg           *kubeadmapi.InitConfiguration
 		expectedError bool
+		isProxy       bool
 	}{
 		{
 			cfg: &kubeadmapi.InitConfiguration{
@@ -85,18 +87,25 @@ func TestCreateLocalEtcdStaticPodManifestFile(t *testing.T) {
 							Endpoints: []string{
 								"https://etcd-instance:2379",
 							},
-							CAFile:   "/etc/kubernetes/pki/etcd/ca.crt",
-							CertFile: "/etc/kubernetes/pki/etcd/apiserver-etcd-client.crt",
-							KeyFile:  "/etc/kubernetes/pki/etcd/apiserver-etcd-client.key",
+							CAFile:      "/etc/kubernetes/pki/etcd/ca.crt",
+							CertFile:    "/etc/kubernetes/pki/etcd/apiserver-etcd-client.crt",
+							KeyFile:     "/etc/kubernetes/pki/etcd/apiserver-etcd-client.key",
+							EnableProxy: true,
 						},
 					},
 				},
 			},
-			expectedError: true,
+			expectedError: false,
+			isProxy:       true,
 		},
 	}
 
 	for _, test := range tests {
+		// Create a fresh temp folder per test case so manifests from one case don't leak
+		// into the file-count assertions of the next.
+		tmpdir := testutil.SetupTempDir(t)
+		defer os.RemoveAll(tmpdir)
+
 		// Execute createStaticPodFunction
 		manifestPath := filepath.Join(tmpdir, kubeadmconstants.ManifestsSubDirName)
 		err := CreateLocalEtcdStaticPodManifestFile(manifestPath, test.cfg)
@@ -105,6 +114,16 @@ func TestCreateLocalEtcdStaticPodManifestFile(t *testing.T) {
 			if err != nil {
 				t.Errorf("CreateLocalEtcdStaticPodManifestFile failed when not expected: %v", err)
 			}
+			if test.isProxy {
+				// The cluster uses an external etcd with the proxy enabled: kubeadm must not
+				// write a local etcd manifest, only the grpc-proxy one.
+				testutil.AssertFilesCount(t, manifestPath, 1)
+				testutil.AssertFileExists(t, manifestPath, externalEtcdProxyManifestName)
+				if _, err := os.Stat(filepath.Join(manifestPath, kubeadmconstants.Etcd+".yaml")); !os.IsNotExist(err) {
+					t.Errorf("expected no local etcd manifest when using an external etcd proxy, but found one (err=%v)", err)
+				}
+				continue
+			}
 			// Assert expected files are there
 			testutil.AssertFilesCount(t, manifestPath, 1)
 			testutil.AssertFileExists(t, manifestPath, kubeadmconstants.Etcd+".yaml")
@@ -120,6 +139,7 @@ func TestGetEtcdCommand(t *testing.T) {
 		cfg            *kubeadmapi.InitConfiguration
 		initialCluster []etcdutil.Member
 		expected       []string
+		expectedError  bool
 	}{
 		{
 			name: "Default args - with empty etcd initial cluster",
@@ -241,11 +261,123 @@ func TestGetEtcdCommand(t *testing.T) {
 				fmt.Sprintf("--initial-cluster=bar=https://1.2.3.4:%d", kubeadmconstants.EtcdListenPeerPort),
 			},
 		},
+		{
+			name: "Denylisted extra arg is rejected",
+			cfg: &kubeadmapi.InitConfiguration{
+				APIEndpoint: kubeadmapi.APIEndpoint{
+					AdvertiseAddress: "1.2.3.4",
+				},
+				NodeRegistration: kubeadmapi.NodeRegistrationOptions{
+					Name: "foo",
+				},
+				ClusterConfiguration: kubeadmapi.ClusterConfiguration{
+					Etcd: kubeadmapi.Etcd{
+						Local: &kubeadmapi.LocalEtcd{
+							DataDir: "/var/lib/etcd",
+							ExtraArgs: map[string]string{
+								"client-cert-auth": "false",
+							},
+						},
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "Allowlisted extra args still flow through",
+			cfg: &kubeadmapi.InitConfiguration{
+				APIEndpoint: kubeadmapi.APIEndpoint{
+					AdvertiseAddress: "1.2.3.4",
+				},
+				NodeRegistration: kubeadmapi.NodeRegistrationOptions{
+					Name: "foo",
+				},
+				ClusterConfiguration: kubeadmapi.ClusterConfiguration{
+					Etcd: kubeadmapi.Etcd{
+						Local: &kubeadmapi.LocalEtcd{
+							DataDir: "/var/lib/etcd",
+							ExtraArgs: map[string]string{
+								"snapshot-count":     "5000",
+								"heartbeat-interval": "250",
+							},
+						},
+					},
+				},
+			},
+			expected: []string{
+				"etcd",
+				"--name=foo",
+				fmt.Sprintf("--listen-client-urls=https://127.0.0.1:%d,https://1.2.3.4:%d", kubeadmconstants.EtcdListenClientPort, kubeadmconstants.EtcdListenClientPort),
+				fmt.Sprintf("--advertise-client-urls=https://1.2.3.4:%d", kubeadmconstants.EtcdListenClientPort),
+				fmt.Sprintf("--listen-peer-urls=https://1.2.3.4:%d", kubeadmconstants.EtcdListenPeerPort),
+				fmt.Sprintf("--initial-advertise-peer-urls=https://1.2.3.4:%d", kubeadmconstants.EtcdListenPeerPort),
+				"--data-dir=/var/lib/etcd",
+				"--cert-file=" + kubeadmconstants.EtcdServerCertName,
+				"--key-file=" + kubeadmconstants.EtcdServerKeyName,
+				"--trusted-ca-file=" + kubeadmconstants.EtcdCACertName,
+				"--client-cert-auth=true",
+				"--peer-cert-file=" + kubeadmconstants.EtcdPeerCertName,
+				"--peer-key-file=" + kubeadmconstants.EtcdPeerKeyName,
+				"--peer-trusted-ca-file=" + kubeadmconstants.EtcdCACertName,
+				"--snapshot-count=5000",
+				"--heartbeat-interval=250",
+				"--peer-client-cert-auth=true",
+				fmt.Sprintf("--initial-cluster=foo=https://1.2.3.4:%d", kubeadmconstants.EtcdListenPeerPort),
+			},
+		},
+		{
+			name: "Additional peer and client URLs are merged into the listen/advertise/initial-cluster flags",
+			cfg: &kubeadmapi.InitConfiguration{
+				APIEndpoint: kubeadmapi.APIEndpoint{
+					AdvertiseAddress: "1.2.3.4",
+				},
+				NodeRegistration: kubeadmapi.NodeRegistrationOptions{
+					Name: "foo",
+				},
+				ClusterConfiguration: kubeadmapi.ClusterConfiguration{
+					Etcd: kubeadmapi.Etcd{
+						Local: &kubeadmapi.LocalEtcd{
+							DataDir:              "/var/lib/etcd",
+							AdditionalPeerURLs:   []string{fmt.Sprintf("https://5.6.7.8:%d", kubeadmconstants.EtcdListenPeerPort)},
+							AdditionalClientURLs: []string{fmt.Sprintf("https://5.6.7.8:%d", kubeadmconstants.EtcdListenClientPort)},
+						},
+					},
+				},
+			},
+			expected: []string{
+				"etcd",
+				"--name=foo",
+				fmt.Sprintf("--listen-client-urls=https://127.0.0.1:%d,https://1.2.3.4:%d,https://5.6.7.8:%d", kubeadmconstants.EtcdListenClientPort, kubeadmconstants.EtcdListenClientPort, kubeadmconstants.EtcdListenClientPort),
+				fmt.Sprintf("--advertise-client-urls=https://1.2.3.4:%d,https://5.6.7.8:%d", kubeadmconstants.EtcdListenClientPort, kubeadmconstants.EtcdListenClientPort),
+				fmt.Sprintf("--listen-peer-urls=https://1.2.3.4:%d,https://5.6.7.8:%d", kubeadmconstants.EtcdListenPeerPort, kubeadmconstants.EtcdListenPeerPort),
+				fmt.Sprintf("--initial-advertise-peer-urls=https://1.2.3.4:%d,https://5.6.7.8:%d", kubeadmconstants.EtcdListenPeerPort, kubeadmconstants.EtcdListenPeerPort),
+				"--data-dir=/var/lib/etcd",
+				"--cert-file=" + kubeadmconstants.EtcdServerCertName,
+				"--key-file=" + kubeadmconstants.EtcdServerKeyName,
+				"--trusted-ca-file=" + kubeadmconstants.EtcdCACertName,
+				"--client-cert-auth=true",
+				"--peer-cert-file=" + kubeadmconstants.EtcdPeerCertName,
+				"--peer-key-file=" + kubeadmconstants.EtcdPeerKeyName,
+				"--peer-trusted-ca-file=" + kubeadmconstants.EtcdCACertName,
+				"--snapshot-count=10000",
+				"--peer-client-cert-auth=true",
+				fmt.Sprintf("--initial-cluster=foo=https://1.2.3.4:%d,foo=https://5.6.7.8:%d", kubeadmconstants.EtcdListenPeerPort, kubeadmconstants.EtcdListenPeerPort),
+			},
+		},
 	}
 
 	for _, rt := range tests {
 		t.Run(rt.name, func(t *testing.T) {
-			actual := getEtcdCommand(rt.cfg, rt.initialCluster)
+			actual, err := getEtcdCommand(rt.cfg, rt.initialCluster)
+			if rt.expectedError {
+				if err == nil {
+					t.Fatalf("expected getEtcdCommand to fail, but it succeeded with: %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getEtcdCommand failed when not expected: %v", err)
+			}
 			sort.Strings(actual)
 			sort.Strings(rt.expected)
 			if !reflect.DeepEqual(actual, rt.expected) {
@@ -253,4 +385,65 @@ func TestGetEtcdCommand(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestPortFromURL(t *testing.T) {
+	var tests = []struct {
+		name         string
+		url          string
+		expectedPort int32
+		expectedOk   bool
+	}{
+		{
+			name:         "valid peer URL",
+			url:          fmt.Sprintf("https://1.2.3.4:%d", kubeadmconstants.EtcdListenPeerPort),
+			expectedPort: int32(kubeadmconstants.EtcdListenPeerPort),
+			expectedOk:   true,
+		},
+		{
+			name:       "URL with no port",
+			url:        "https://1.2.3.4",
+			expectedOk: false,
+		},
+		{
+			name:       "not a URL at all",
+			url:        "not a url",
+			expectedOk: false,
+		},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			port, ok := portFromURL(rt.url)
+			if ok != rt.expectedOk {
+				t.Fatalf("portFromURL(%q) ok = %v, expected %v", rt.url, ok, rt.expectedOk)
+			}
+			if ok && port != rt.expectedPort {
+				t.Errorf("portFromURL(%q) = %d, expected %d", rt.url, port, rt.expectedPort)
+			}
+		})
+	}
+}
+
+func TestAdditionalURLHostPorts(t *testing.T) {
+	cfg := &kubeadmapi.InitConfiguration{
+		ClusterConfiguration: kubeadmapi.ClusterConfiguration{
+			Etcd: kubeadmapi.Etcd{
+				Local: &kubeadmapi.LocalEtcd{
+					DataDir:              "/var/lib/etcd",
+					AdditionalPeerURLs:   []string{fmt.Sprintf("https://5.6.7.8:%d", kubeadmconstants.EtcdListenPeerPort)},
+					AdditionalClientURLs: []string{fmt.Sprintf("https://5.6.7.8:%d", kubeadmconstants.EtcdListenClientPort)},
+				},
+			},
+		},
+	}
+
+	ports := additionalURLHostPorts(cfg)
+	expected := []v1.ContainerPort{
+		{Name: "peer-additional", HostPort: int32(kubeadmconstants.EtcdListenPeerPort), ContainerPort: int32(kubeadmconstants.EtcdListenPeerPort)},
+		{Name: "client-additional", HostPort: int32(kubeadmconstants.EtcdListenClientPort), ContainerPort: int32(kubeadmconstants.EtcdListenClientPort)},
+	}
+	if !reflect.DeepEqual(ports, expected) {
+		t.Errorf("additionalURLHostPorts() = %v, expected %v", ports, expected)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+// externalEtcdProxyManifestName is the static pod manifest filename for the grpc-proxy pod.
+// It is deliberately distinct from kubeadmconstants.Etcd+".yaml" since, unlike that file, it
+// coexists with an external (not kubeadm-managed) etcd cluster rather than replacing it.
+const externalEtcdProxyManifestName = "kube-etcd-proxy.yaml"
+
+// externalEtcdProxyListenAddress is where the proxy listens for client traffic. It matches
+// the address kube-apiserver's loopback etcd config already points at, so that config needs
+// no changes when a cluster switches between a local and an external+proxied etcd.
+const externalEtcdProxyListenAddress = "127.0.0.1:2379"
+
+// CreateExternalEtcdProxyStaticPodManifestFile writes a static pod manifest that runs
+// `etcd grpc-proxy start` in front of an external etcd cluster, listening on
+// externalEtcdProxyListenAddress. This lets kube-apiserver keep talking to a local address
+// even when Etcd.External is configured, instead of needing to know about every external
+// endpoint itself.
+func CreateExternalEtcdProxyStaticPodManifestFile(manifestDir string, ext *kubeadmapi.ExternalEtcd) error {
+	if err := validateProxyExtraArgs(ext.ProxyExtraArgs); err != nil {
+		return err
+	}
+
+	pod := &v1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kube-etcd-proxy",
+			Namespace: "kube-system",
+		},
+		Spec: v1.PodSpec{
+			HostNetwork: true,
+			Containers: []v1.Container{
+				{
+					Name:    "kube-etcd-proxy",
+					Command: getEtcdProxyCommand(ext),
+				},
+			},
+		},
+	}
+
+	data, err := yaml.Marshal(pod)
+	if err != nil {
+		return fmt.Errorf("failed to marshal etcd proxy static pod manifest: %v", err)
+	}
+
+	if err := os.MkdirAll(manifestDir, 0700); err != nil {
+		return fmt.Errorf("failed to create manifest directory %q: %v", manifestDir, err)
+	}
+
+	return ioutil.WriteFile(filepath.Join(manifestDir, externalEtcdProxyManifestName), data, 0600)
+}
+
+// getEtcdProxyCommand builds the `etcd grpc-proxy start` command for ext.
+func getEtcdProxyCommand(ext *kubeadmapi.ExternalEtcd) []string {
+	defaultArgs := Args{
+		"endpoints":   strings.Join(ext.Endpoints, ","),
+		"cacert":      ext.CAFile,
+		"cert":        ext.CertFile,
+		"key":         ext.KeyFile,
+		"listen-addr": externalEtcdProxyListenAddress,
+		"metrics":     "extensive",
+	}
+
+	for k, v := range ext.ProxyExtraArgs {
+		defaultArgs[k] = v
+	}
+
+	keys := make([]string, 0, len(defaultArgs))
+	for k := range defaultArgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	command := []string{"etcd", "grpc-proxy", "start"}
+	for _, k := range keys {
+		command = append(command, fmt.Sprintf("--%s=%s", k, defaultArgs[k]))
+	}
+	return command
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/clientv3"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+)
+
+const etcdTimeout = 20 * time.Second
+
+// ClusterStatus is a trimmed-down view of an etcd cluster's status as reported by the
+// member kubeadm happens to be talking to.
+type ClusterStatus struct {
+	// ClusterID is the etcd cluster ID as reported in the response header.
+	ClusterID uint64
+	// Revision is the MVCC store revision as reported in the response header. This is
+	// distinct from RaftIndex: it counts key-space mutations, not raft log entries.
+	Revision int64
+	// RaftIndex is the leader's committed raft index at the time of the call.
+	RaftIndex uint64
+}
+
+// ClusterClient is the subset of etcd's cluster administration API that kubeadm needs in
+// order to add, list, promote and inspect members. It is implemented by *Client and is the
+// seam tests stub out.
+type ClusterClient interface {
+	MemberAddAsLearner(peerURL string) error
+	MemberList() ([]Member, error)
+	MemberPromote(name string) error
+	Status() (*ClusterStatus, error)
+	RaftAppliedIndex(member Member) (uint64, error)
+	Close() error
+}
+
+// Client is a wrapper around the etcd v3 client that talks to the local (or a joining)
+// etcd cluster using the same TLS material kubeadm renders for the static pod.
+type Client struct {
+	client *clientv3.Client
+}
+
+// NewClientFromInitConfiguration creates a Client configured with the TLS material
+// referenced by kubeadmconstants.EtcdCACertName/EtcdServerCertName/EtcdServerKeyName,
+// dialing the client endpoints of the members described by initialCluster.
+func NewClientFromInitConfiguration(cfg *kubeadmapi.InitConfiguration, initialCluster []Member) (*Client, error) {
+	endpoints := make([]string, 0, len(initialCluster))
+	for _, m := range initialCluster {
+		endpoint, err := m.ClientEndpoint()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't determine client endpoint for member %q: %v", m.Name, err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	tlsConfig, err := clientTLSConfig(kubeadmconstants.CertificateDir)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't build etcd client TLS config: %v", err)
+	}
+
+	c, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: etcdTimeout,
+		TLS:         tlsConfig,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{client: c}, nil
+}
+
+// MemberAddAsLearner adds peerURL to the cluster as a non-voting learner member.
+func (c *Client) MemberAddAsLearner(peerURL string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdTimeout)
+	defer cancel()
+	_, err := c.client.MemberAddAsLearner(ctx, []string{peerURL})
+	return err
+}
+
+// MemberPromote promotes the named learner to a full voting member.
+func (c *Client) MemberPromote(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdTimeout)
+	defer cancel()
+	resp, err := c.client.MemberList(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range resp.Members {
+		if m.Name != name {
+			continue
+		}
+		promoteCtx, promoteCancel := context.WithTimeout(context.Background(), etcdTimeout)
+		defer promoteCancel()
+		_, err := c.client.MemberPromote(promoteCtx, m.ID)
+		return err
+	}
+	return fmt.Errorf("member %q not found", name)
+}
+
+// MemberList returns the members currently known to the cluster.
+func (c *Client) MemberList() ([]Member, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdTimeout)
+	defer cancel()
+	resp, err := c.client.MemberList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]Member, 0, len(resp.Members))
+	for _, m := range resp.Members {
+		peerURL := ""
+		if len(m.PeerURLs) > 0 {
+			peerURL = m.PeerURLs[0]
+		}
+		clientURL := ""
+		if len(m.ClientURLs) > 0 {
+			clientURL = m.ClientURLs[0]
+		}
+		members = append(members, Member{Name: m.Name, PeerURL: peerURL, ClientURL: clientURL})
+	}
+	return members, nil
+}
+
+// Status returns the cluster status as observed from the member this client is connected to.
+func (c *Client) Status() (*ClusterStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdTimeout)
+	defer cancel()
+	resp, err := c.client.Status(ctx, c.client.Endpoints()[0])
+	if err != nil {
+		return nil, err
+	}
+	return &ClusterStatus{ClusterID: resp.Header.ClusterId, Revision: resp.Header.Revision, RaftIndex: resp.RaftIndex}, nil
+}
+
+// RaftAppliedIndex returns the given member's applied raft index.
+func (c *Client) RaftAppliedIndex(member Member) (uint64, error) {
+	endpoint, err := member.ClientEndpoint()
+	if err != nil {
+		return 0, fmt.Errorf("couldn't determine client endpoint for member %q: %v", member.Name, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), etcdTimeout)
+	defer cancel()
+	resp, err := c.client.Status(ctx, endpoint)
+	if err != nil {
+		return 0, err
+	}
+	return resp.RaftAppliedIndex, nil
+}
+
+// Close releases the underlying etcd client connection.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// clientTLSConfig loads the CA/cert/key kubeadm renders for etcd from certDir and builds a
+// tls.Config suitable for dialing the cluster as a client.
+func clientTLSConfig(certDir string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(
+		filepath.Join(certDir, kubeadmconstants.EtcdServerCertName),
+		filepath.Join(certDir, kubeadmconstants.EtcdServerKeyName),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load etcd client certificate: %v", err)
+	}
+
+	caData, err := ioutil.ReadFile(filepath.Join(certDir, kubeadmconstants.EtcdCACertName))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read etcd CA certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caData) {
+		return nil, fmt.Errorf("no certificates found in %s", kubeadmconstants.EtcdCACertName)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+	}, nil
+}
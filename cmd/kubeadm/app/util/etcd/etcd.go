@@ -0,0 +1,86 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package etcd provides kubeadm-internal helpers for talking to and
+// reasoning about an etcd cluster.
+package etcd
+
+import (
+	"fmt"
+	"net/url"
+
+	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+)
+
+// Member contains information about an etcd member to add or that is already part of
+// the initial-cluster list.
+type Member struct {
+	// Name is the name of the member.
+	Name string
+	// PeerURL is the peer URL used to reach the member. This is what etcd's own
+	// --initial-cluster flag expects, and is NOT something a Client can dial: etcd's peer
+	// port only serves the raft transport, not the client/cluster gRPC API.
+	PeerURL string
+	// PeerURLs lists additional peer URLs the member is reachable on, beyond PeerURL. A
+	// member carries more than one URL during a live control-plane migration, where both
+	// its old and new data-plane address must resolve while the move is in progress.
+	PeerURLs []string
+	// ClientURL is the client URL administrative calls (MemberList, MemberAddAsLearner,
+	// Status, ...) should dial. If empty, ClientEndpoint derives it from PeerURL by
+	// swapping the peer port for the client port, which holds for any member kubeadm itself
+	// rendered the static pod manifest for.
+	ClientURL string
+}
+
+// AllPeerURLs returns the member's PeerURL followed by PeerURLs, with duplicates removed and
+// order preserved.
+func (m Member) AllPeerURLs() []string {
+	urls := append([]string{m.PeerURL}, m.PeerURLs...)
+	seen := make(map[string]bool, len(urls))
+	unique := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		unique = append(unique, u)
+	}
+	return unique
+}
+
+// ClientEndpoint returns the client URL a Client should dial to reach this member: ClientURL
+// if set, otherwise PeerURL with the peer port replaced by etcd's client port.
+func (m Member) ClientEndpoint() (string, error) {
+	if m.ClientURL != "" {
+		return m.ClientURL, nil
+	}
+	return peerURLToClientURL(m.PeerURL)
+}
+
+// peerURLToClientURL rewrites a peer URL's port to kubeadmconstants.EtcdListenClientPort,
+// since kubeadm always runs a member's client and peer listeners on the same host.
+func peerURLToClientURL(peerURL string) (string, error) {
+	u, err := url.Parse(peerURL)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse peer URL %q: %v", peerURL, err)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return "", fmt.Errorf("peer URL %q has no host", peerURL)
+	}
+	u.Host = fmt.Sprintf("%s:%d", host, kubeadmconstants.EtcdListenClientPort)
+	return u.String(), nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllPeerURLs(t *testing.T) {
+	var tests = []struct {
+		name     string
+		member   Member
+		expected []string
+	}{
+		{
+			name:     "only PeerURL set",
+			member:   Member{PeerURL: "https://1.2.3.4:2380"},
+			expected: []string{"https://1.2.3.4:2380"},
+		},
+		{
+			name: "PeerURL and PeerURLs, duplicates removed",
+			member: Member{
+				PeerURL:  "https://1.2.3.4:2380",
+				PeerURLs: []string{"https://1.2.3.4:2380", "https://5.6.7.8:2380"},
+			},
+			expected: []string{"https://1.2.3.4:2380", "https://5.6.7.8:2380"},
+		},
+		{
+			name:     "empty member",
+			member:   Member{},
+			expected: nil,
+		},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			actual := rt.member.AllPeerURLs()
+			if len(actual) == 0 && len(rt.expected) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(actual, rt.expected) {
+				t.Errorf("AllPeerURLs() = %v, expected %v", actual, rt.expected)
+			}
+		})
+	}
+}
+
+func TestClientEndpoint(t *testing.T) {
+	var tests = []struct {
+		name          string
+		member        Member
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:     "ClientURL set takes precedence over PeerURL",
+			member:   Member{PeerURL: "https://1.2.3.4:2380", ClientURL: "https://1.2.3.4:2379"},
+			expected: "https://1.2.3.4:2379",
+		},
+		{
+			name:     "ClientURL unset, derived from PeerURL",
+			member:   Member{PeerURL: "https://1.2.3.4:2380"},
+			expected: "https://1.2.3.4:2379",
+		},
+		{
+			name:          "PeerURL cannot be parsed",
+			member:        Member{PeerURL: "://bad-url"},
+			expectedError: true,
+		},
+		{
+			name:          "PeerURL has no host",
+			member:        Member{PeerURL: "https://"},
+			expectedError: true,
+		},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			actual, err := rt.member.ClientEndpoint()
+			if rt.expectedError {
+				if err == nil {
+					t.Fatalf("expected ClientEndpoint to fail, but it succeeded with: %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ClientEndpoint failed when not expected: %v", err)
+			}
+			if actual != rt.expected {
+				t.Errorf("ClientEndpoint() = %q, expected %q", actual, rt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testutil holds helpers shared by kubeadm's unit tests.
+package testutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// SetupTempDir creates a temporary directory for use in a test case and returns its path.
+func SetupTempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "kubeadm-test")
+	if err != nil {
+		t.Fatalf("couldn't create temporary directory: %v", err)
+	}
+	return dir
+}
+
+// AssertFileExists asserts that a file with the given name exists in dirname.
+func AssertFileExists(t *testing.T, dirname, filename string) {
+	path := filepath.Join(dirname, filename)
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("file %s does not exist: %v", path, err)
+	}
+}
+
+// AssertFilesCount asserts that dirname contains exactly count files.
+func AssertFilesCount(t *testing.T, dirname string, count int) {
+	files, err := ioutil.ReadDir(dirname)
+	if err != nil {
+		t.Fatalf("couldn't read directory %s: %v", dirname, err)
+	}
+	if len(files) != count {
+		t.Errorf("dir %s contains %d files, expected %d", dirname, len(files), count)
+	}
+}
+
+// AssertError asserts that err is non-nil and its message contains contains.
+func AssertError(t *testing.T, err error, contains string) {
+	if err == nil {
+		t.Errorf("expected an error containing %q, got nil", contains)
+		return
+	}
+	if !strings.Contains(err.Error(), contains) {
+		t.Errorf("expected error to contain %q, got %q", contains, err.Error())
+	}
+}